@@ -0,0 +1,169 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+)
+
+// GetFormula returns the formula associated with the cell, or an empty
+// string if the cell has no formula. The returned formula does not include
+// the leading '='.
+func (c Cell) GetFormula() string {
+	if c.X().F == nil {
+		return ""
+	}
+	return c.X().F.Content
+}
+
+// GetValue returns the displayed string value of the cell: the cached
+// result for formula cells, the resolved shared/inline string for string
+// cells, TRUE or FALSE for booleans, and the raw text for errors.
+// Numeric cells are formatted according to the number format applied by the
+// cell's style, falling back to the raw value when the style can't be
+// resolved.
+func (c Cell) GetValue() (string, error) {
+	x := c.X()
+	switch x.TAttr {
+	case sml.ST_CellTypeS:
+		// shared string: the cell value is an index into the workbook's
+		// shared string table
+		if x.V == nil {
+			return "", nil
+		}
+		idx, err := parseSharedStringIndex(*x.V)
+		if err != nil {
+			return "", fmt.Errorf("error parsing shared string index %s: %s", *x.V, err)
+		}
+		return c.resolveSharedString(idx)
+	case sml.ST_CellTypeInlineStr:
+		if x.Is == nil {
+			return "", nil
+		}
+		return inlineStringText(x.Is), nil
+	case sml.ST_CellTypeStr:
+		// formula result cached as a string
+		if x.V == nil {
+			return "", nil
+		}
+		return *x.V, nil
+	case sml.ST_CellTypeB:
+		if x.V == nil {
+			return "", nil
+		}
+		if *x.V == "1" {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case sml.ST_CellTypeE:
+		if x.V == nil {
+			return "", nil
+		}
+		return *x.V, nil
+	default:
+		// numeric cell (the common, unset TAttr case)
+		if x.V == nil {
+			return "", nil
+		}
+		if s, ok := c.formatNumber(*x.V); ok {
+			return s, nil
+		}
+		return *x.V, nil
+	}
+}
+
+// GetCellValue returns the displayed string value of the cell referenced by
+// ref (e.g. "B2"), creating no new cells or rows in the process.
+func (s Sheet) GetCellValue(ref string) (string, error) {
+	col, row, err := ParseCellReference(ref)
+	if err != nil {
+		return "", fmt.Errorf("error parsing cell reference %s: %s", ref, err)
+	}
+	for _, r := range s.x.SheetData.Row {
+		if r.RAttr == nil || *r.RAttr != row {
+			continue
+		}
+		for _, cx := range r.C {
+			if cx.RAttr != nil && *cx.RAttr == col+fmt.Sprint(row) {
+				return Cell{s.w, r, cx}.GetValue()
+			}
+		}
+	}
+	return "", nil
+}
+
+// parseSharedStringIndex parses the numeric shared-string index stored in a
+// cell's raw value.
+func parseSharedStringIndex(v string) (int, error) {
+	return strconv.Atoi(v)
+}
+
+// inlineStringText returns the plain text of an inline string, concatenating
+// the text of all of its runs if it doesn't have a single top-level value.
+func inlineStringText(is *sml.CT_Rst) string {
+	if is.T != nil {
+		return *is.T
+	}
+	s := ""
+	for _, r := range is.R {
+		if r.T != "" {
+			s += r.T
+		}
+	}
+	return s
+}
+
+// resolveSharedString looks up a string by index in the workbook's shared
+// string table.
+func (c Cell) resolveSharedString(idx int) (string, error) {
+	sst := c.w.SharedStrings.X()
+	if sst == nil || idx < 0 || idx >= len(sst.Si) {
+		return "", fmt.Errorf("shared string index %d out of range", idx)
+	}
+	return inlineStringText(sst.Si[idx]), nil
+}
+
+// formatNumber applies the number format associated with the cell's style to
+// its raw numeric value. It returns ok=false when the style can't be
+// resolved or its format is not a plain fixed-decimal one, in which case
+// callers should fall back to the raw value.
+func (c Cell) formatNumber(raw string) (string, bool) {
+	if c.X().SAttr == nil {
+		return "", false
+	}
+	f, ok := c.w.StyleSheet.GetNumberFormat(*c.X().SAttr)
+	if !ok || f == "" || f == "General" {
+		return "", false
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return "", false
+	}
+	// Only the common fixed-decimal formats (e.g. "0", "0.00", "#,##0.00")
+	// are interpreted; anything fancier (dates, percentages, custom sections)
+	// is left to the raw value so callers still get a usable string. Only the
+	// number of decimals is taken from the format: thousands separators are
+	// not inserted.
+	if strings.Trim(f, "0#,.") != "" {
+		return "", false
+	}
+	decimals := 0
+	if dot := strings.IndexByte(f, '.'); dot != -1 {
+		for _, r := range f[dot+1:] {
+			if r != '0' && r != '#' {
+				break
+			}
+			decimals++
+		}
+	}
+	return strconv.FormatFloat(v, 'f', decimals, 64), true
+}
@@ -0,0 +1,62 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"testing"
+
+	sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+)
+
+func TestGetValueFormatsNumberUsingStyle(t *testing.T) {
+	s := newTestSheet()
+
+	ss := sml.NewStyleSheet()
+	ss.CellXfs = sml.NewCT_CellXfs()
+	xf := sml.NewCT_Xf()
+	numFmtID := uint32(164)
+	xf.NumFmtIdAttr = &numFmtID
+	ss.CellXfs.Xf = append(ss.CellXfs.Xf, xf)
+	ss.NumFmts = sml.NewCT_NumFmts()
+	nf := sml.NewCT_NumFmt()
+	nf.NumFmtIdAttr = numFmtID
+	nf.FormatCodeAttr = "0.00"
+	ss.NumFmts.NumFmt = append(ss.NumFmts.NumFmt, nf)
+	s.w.StyleSheet = StyleSheet{ss}
+
+	styleIdx := uint32(0)
+	c := s.Cell("A1")
+	c.X().SAttr = &styleIdx
+	c.X().V = strPtr("3.14159")
+
+	v, err := c.GetValue()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v != "3.14" {
+		t.Fatalf("expected the cell's style to format the number to 2 decimals, got %q", v)
+	}
+}
+
+func TestGetValueFallsBackToRawValueWithoutStyle(t *testing.T) {
+	s := newTestSheet()
+	c := s.Cell("A1")
+	c.X().V = strPtr("3.14159")
+
+	v, err := c.GetValue()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v != "3.14159" {
+		t.Fatalf("expected the raw value with no style applied, got %q", v)
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
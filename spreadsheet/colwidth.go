@@ -0,0 +1,186 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"log"
+
+	"baliance.com/gooxml"
+	sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+)
+
+// colWidthPadding is the extra width, in characters, Excel adds around the
+// widest piece of content in a column.
+const colWidthPadding = 0.71
+
+// baseFontSize is the point size (11pt Calibri) that estimatedCellWidth's
+// character-width metric is calibrated against; other sizes are scaled
+// relative to it.
+const baseFontSize = 11.0
+
+// AutoFitColumns sets the width of every column that has at least one
+// non-empty cell to fit its widest cell, using an approximate Calibri 11 character-width
+// metric rather than an actual font renderer.
+func (s Sheet) AutoFitColumns() {
+	widths := map[string]float64{}
+	for _, row := range s.x.SheetData.Row {
+		for _, c := range row.C {
+			if c.RAttr == nil {
+				continue
+			}
+			col, _, err := ParseCellReference(*c.RAttr)
+			if err != nil {
+				continue
+			}
+			cell := Cell{s.w, row, c}
+			w := s.estimatedCellWidth(cell)
+			if w > widths[col] {
+				widths[col] = w
+			}
+		}
+	}
+	for col, w := range widths {
+		s.setColWidth(col, w+colWidthPadding)
+	}
+}
+
+// SetColWidthAuto sets the width of col (e.g. "C") to fit the widest cell
+// currently present in that column.
+func (s Sheet) SetColWidthAuto(col string) {
+	max := 0.0
+	for _, row := range s.x.SheetData.Row {
+		for _, c := range row.C {
+			if c.RAttr == nil {
+				continue
+			}
+			cc, _, err := ParseCellReference(*c.RAttr)
+			if err != nil || cc != col {
+				continue
+			}
+			if w := s.estimatedCellWidth(Cell{s.w, row, c}); w > max {
+				max = w
+			}
+		}
+	}
+	s.setColWidth(col, max+colWidthPadding)
+}
+
+// estimatedCellWidth approximates the displayed width of a cell's contents,
+// in characters of 11pt Calibri, scaling for the cell's font size and
+// applying a small multiplier for bold/italic styles.
+func (s Sheet) estimatedCellWidth(c Cell) float64 {
+	v, err := c.GetValue()
+	if err != nil || v == "" {
+		return 0
+	}
+	width := float64(len(v))
+
+	if c.X().SAttr != nil {
+		if sz, bold, italic, ok := s.w.StyleSheet.FontMetrics(*c.X().SAttr); ok {
+			width *= sz / baseFontSize
+			if bold {
+				width *= 1.1
+			}
+			if italic {
+				width *= 1.05
+			}
+		}
+	}
+	return width
+}
+
+// setColWidth sets the width of a single column, creating the <cols>/<col>
+// entry if it doesn't already exist. If idx falls within a wider existing
+// <col> span (e.g. a sheet-wide default width entry), that span is split so
+// only idx's width changes; the rest of the span keeps its original width.
+func (s Sheet) setColWidth(col string, width float64) {
+	idx := columnToIndex(col)
+	if idx == 0 {
+		log.Printf("error setting width of column %s: invalid column reference", col)
+		return
+	}
+
+	if s.x.Cols == nil {
+		s.x.Cols = []*sml.CT_Cols{sml.NewCT_Cols()}
+	}
+	cols := s.x.Cols[0]
+	for i, c := range cols.Col {
+		if idx < c.MinAttr || idx > c.MaxAttr {
+			continue
+		}
+		if c.MinAttr == idx && c.MaxAttr == idx {
+			// the span is exactly this one column; overwrite in place
+			c.WidthAttr = gooxml.Float64(width)
+			c.CustomWidthAttr = gooxml.Bool(true)
+			return
+		}
+
+		// split the span into up to three: everything before idx (keeping
+		// c's original width), idx itself (the new width), and everything
+		// after idx (keeping c's original width). The "before" and "after"
+		// pieces are copies of c, and c itself is replaced by the pieces.
+		var replacement []*sml.CT_Col
+		if idx > c.MinAttr {
+			before := sml.NewCT_Col()
+			*before = *c
+			before.MaxAttr = idx - 1
+			replacement = append(replacement, before)
+		}
+
+		newCol := sml.NewCT_Col()
+		newCol.MinAttr = idx
+		newCol.MaxAttr = idx
+		newCol.WidthAttr = gooxml.Float64(width)
+		newCol.CustomWidthAttr = gooxml.Bool(true)
+		replacement = append(replacement, newCol)
+
+		if idx < c.MaxAttr {
+			after := sml.NewCT_Col()
+			*after = *c
+			after.MinAttr = idx + 1
+			replacement = append(replacement, after)
+		}
+
+		cols.Col = append(cols.Col[:i], append(replacement, cols.Col[i+1:]...)...)
+		return
+	}
+
+	c := sml.NewCT_Col()
+	c.MinAttr = idx
+	c.MaxAttr = idx
+	c.WidthAttr = gooxml.Float64(width)
+	c.CustomWidthAttr = gooxml.Bool(true)
+	cols.Col = append(cols.Col, c)
+}
+
+// hideColumn hides col, giving it its own isolated <col> entry first (via
+// setColWidth) so hiding it doesn't affect any other column that happened
+// to share a span with it.
+func (s Sheet) hideColumn(col string) {
+	s.setColWidth(col, 0)
+	idx := columnToIndex(col)
+	for _, c := range s.x.Cols[0].Col {
+		if c.MinAttr == idx && c.MaxAttr == idx {
+			c.HiddenAttr = gooxml.Bool(true)
+			return
+		}
+	}
+}
+
+// columnToIndex converts a column reference like "A" or "AB" to its 1-based
+// column index, returning 0 if col is not a valid column reference.
+func columnToIndex(col string) uint32 {
+	idx := uint32(0)
+	for _, r := range col {
+		if r < 'A' || r > 'Z' {
+			return 0
+		}
+		idx = idx*26 + uint32(r-'A'+1)
+	}
+	return idx
+}
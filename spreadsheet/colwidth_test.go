@@ -0,0 +1,103 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"testing"
+
+	"baliance.com/gooxml"
+	sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+)
+
+func TestColumnToIndex(t *testing.T) {
+	cases := map[string]uint32{
+		"A":  1,
+		"Z":  26,
+		"AA": 27,
+		"AB": 28,
+	}
+	for col, want := range cases {
+		if got := columnToIndex(col); got != want {
+			t.Errorf("columnToIndex(%q) = %d, want %d", col, got, want)
+		}
+	}
+	if got := columnToIndex("1A"); got != 0 {
+		t.Errorf("columnToIndex(%q) = %d, want 0", "1A", got)
+	}
+}
+
+func TestEstimatedCellWidthScalesWithFontSize(t *testing.T) {
+	s := newTestSheet()
+
+	ss := sml.NewStyleSheet()
+	ss.CellXfs = sml.NewCT_CellXfs()
+	xf := sml.NewCT_Xf()
+	fontID := uint32(0)
+	xf.FontIdAttr = &fontID
+	ss.CellXfs.Xf = append(ss.CellXfs.Xf, xf)
+	ss.Fonts = sml.NewCT_Fonts()
+	font := sml.NewCT_Font()
+	font.Sz = sml.NewCT_FontSize()
+	font.Sz.ValAttr = 22 // double the 11pt baseline
+	ss.Fonts.Font = append(ss.Fonts.Font, font)
+	s.w.StyleSheet = StyleSheet{ss}
+
+	styleIdx := uint32(0)
+	c := s.Cell("A1")
+	c.X().SAttr = &styleIdx
+	v := "12345"
+	c.X().V = &v
+
+	got := s.estimatedCellWidth(c)
+	want := float64(len(v)) * 2
+	if got != want {
+		t.Fatalf("expected a 22pt font to double the estimated width to %v, got %v", want, got)
+	}
+}
+
+func TestSetColWidthSplitsExistingSpan(t *testing.T) {
+	s := newTestSheet()
+	s.x.Cols = []*sml.CT_Cols{sml.NewCT_Cols()}
+	def := sml.NewCT_Col()
+	def.MinAttr = 1
+	def.MaxAttr = 16384
+	def.WidthAttr = gooxml.Float64(8.43)
+	s.x.Cols[0].Col = append(s.x.Cols[0].Col, def)
+
+	s.setColWidth("C", 20)
+
+	cols := s.x.Cols[0].Col
+	if len(cols) != 3 {
+		t.Fatalf("expected the default span to split into 3 entries, got %d: %+v", len(cols), cols)
+	}
+
+	// column C (index 3) should have the new width ...
+	found := false
+	for _, c := range cols {
+		if c.MinAttr == 3 && c.MaxAttr == 3 {
+			found = true
+			if c.WidthAttr == nil || *c.WidthAttr != 20 {
+				t.Errorf("expected column C to have width 20, got %v", c.WidthAttr)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("no single-column entry found for column C: %+v", cols)
+	}
+
+	// ... and every other column covered by the original span should be
+	// untouched.
+	for _, c := range cols {
+		if c.MinAttr == 3 && c.MaxAttr == 3 {
+			continue
+		}
+		if c.WidthAttr == nil || *c.WidthAttr != 8.43 {
+			t.Errorf("expected span %d-%d to keep the original width 8.43, got %v", c.MinAttr, c.MaxAttr, c.WidthAttr)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"baliance.com/gooxml"
+	sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+)
+
+// maxInlineListLength is the maximum length Excel allows for an inline list
+// formula (e.g. `"A,B,C"`) before it silently truncates the dropdown.
+const maxInlineListLength = 255
+
+// ValidationOp is the comparison operator used by a numeric range data
+// validation.
+type ValidationOp byte
+
+// ValidationOp constants.
+const (
+	ValidationOpBetween ValidationOp = iota
+	ValidationOpNotBetween
+	ValidationOpEqual
+	ValidationOpNotEqual
+	ValidationOpGreaterThan
+	ValidationOpLessThan
+	ValidationOpGreaterThanOrEqual
+	ValidationOpLessThanOrEqual
+)
+
+func (v ValidationOp) toST() sml.ST_DataValidationOperator {
+	switch v {
+	case ValidationOpNotBetween:
+		return sml.ST_DataValidationOperatorNotBetween
+	case ValidationOpEqual:
+		return sml.ST_DataValidationOperatorEqual
+	case ValidationOpNotEqual:
+		return sml.ST_DataValidationOperatorNotEqual
+	case ValidationOpGreaterThan:
+		return sml.ST_DataValidationOperatorGreaterThan
+	case ValidationOpLessThan:
+		return sml.ST_DataValidationOperatorLessThan
+	case ValidationOpGreaterThanOrEqual:
+		return sml.ST_DataValidationOperatorGreaterThanOrEqual
+	case ValidationOpLessThanOrEqual:
+		return sml.ST_DataValidationOperatorLessThanOrEqual
+	default:
+		return sml.ST_DataValidationOperatorBetween
+	}
+}
+
+// DataValidation is used to build up a data validation constraint (a
+// dropdown list or a numeric range, for example) on a range of cells within
+// a sheet.
+type DataValidation struct {
+	s Sheet
+	x *sml.CT_DataValidation
+}
+
+// X returns the inner wrapped XML type.
+func (d DataValidation) X() *sml.CT_DataValidation {
+	return d.x
+}
+
+// AddDataValidation adds a new, empty data validation to the sheet and
+// returns it so it can be configured via SetRange/SetList/etc.
+func (s Sheet) AddDataValidation() DataValidation {
+	if s.x.DataValidations == nil {
+		s.x.DataValidations = sml.NewCT_DataValidations()
+	}
+	dv := sml.NewCT_DataValidation()
+	s.x.DataValidations.DataValidation = append(s.x.DataValidations.DataValidation, dv)
+	s.x.DataValidations.CountAttr = gooxml.Uint32(uint32(len(s.x.DataValidations.DataValidation)))
+	return DataValidation{s, dv}
+}
+
+// SetRange sets the cell range the validation applies to (e.g. "A2:A100").
+// Calling SetRange more than once extends the validation to cover multiple,
+// space separated ranges.
+func (d DataValidation) SetRange(rangeRef string) {
+	if d.x.SqrefAttr == "" {
+		d.x.SqrefAttr = rangeRef
+	} else {
+		d.x.SqrefAttr += " " + rangeRef
+	}
+}
+
+// listHelperColumn is a column far enough to the right of normal content
+// (the last column a worksheet can have) that writing a helper list to it
+// is very unlikely to collide with data the caller put on the sheet.
+const listHelperColumn = "XFD"
+
+// SetList constrains the range to the given list of values, shown to the
+// user as a dropdown. Inline list formulas (e.g. `"A,B,C"`) are truncated by
+// Excel past 255 characters; rather than emit a formula Excel will clip,
+// SetList writes values that don't fit inline into a hidden helper column
+// and validates against that range instead, the same workaround Excel
+// itself needs when there's no x14 extension list support to fall back on.
+func (d DataValidation) SetList(values []string) {
+	formula := fmt.Sprintf(`"%s"`, strings.Join(values, ","))
+	if len(formula) <= maxInlineListLength {
+		d.x.TypeAttr = sml.ST_DataValidationTypeList
+		d.x.Formula1 = formula
+		return
+	}
+
+	ref, err := d.s.writeListHelperRange(values)
+	if err != nil {
+		log.Printf("error writing data validation list helper range, falling back to an inline formula Excel may truncate: %s", err)
+		d.x.TypeAttr = sml.ST_DataValidationTypeList
+		d.x.Formula1 = formula
+		return
+	}
+	d.SetRangeList(ref)
+}
+
+// writeListHelperRange writes values down listHelperColumn starting at the
+// first row not already claimed by an earlier SetList helper range (so that
+// multiple long lists on the same sheet each get their own rows instead of
+// overwriting one another), hides the column, and returns a range reference
+// covering the written values suitable for SetRangeList.
+func (s Sheet) writeListHelperRange(values []string) (string, error) {
+	start := s.nextListHelperRow()
+	for i, v := range values {
+		s.Cell(fmt.Sprintf("%s%d", listHelperColumn, start+uint32(i))).SetString(v)
+	}
+	s.hideColumn(listHelperColumn)
+	end := start + uint32(len(values)) - 1
+	return s.RangeReference(fmt.Sprintf("%s%d:%s%d", listHelperColumn, start, listHelperColumn, end)), nil
+}
+
+// nextListHelperRow returns the first row of listHelperColumn not already
+// used by a previous SetList helper range.
+func (s Sheet) nextListHelperRow() uint32 {
+	max := uint32(0)
+	for _, r := range s.x.SheetData.Row {
+		for _, c := range r.C {
+			if c.RAttr == nil {
+				continue
+			}
+			col, row, err := ParseCellReference(*c.RAttr)
+			if err != nil || col != listHelperColumn {
+				continue
+			}
+			if row > max {
+				max = row
+			}
+		}
+	}
+	return max + 1
+}
+
+// SetRangeList constrains the range to the values found at another range or
+// defined name (e.g. "Sheet2!$A$1:$A$10"), avoiding the 255 character limit
+// that an inline SetList formula is subject to.
+func (d DataValidation) SetRangeList(formula string) {
+	d.x.TypeAttr = sml.ST_DataValidationTypeList
+	d.x.Formula1 = formula
+}
+
+// SetNumberRange constrains the range to numeric values matching op relative
+// to min and max (max is ignored for single-operand operators).
+func (d DataValidation) SetNumberRange(min, max float64, op ValidationOp) {
+	d.x.TypeAttr = sml.ST_DataValidationTypeDecimal
+	d.x.OperatorAttr = op.toST()
+	d.x.Formula1 = fmt.Sprintf("%v", min)
+	if op == ValidationOpBetween || op == ValidationOpNotBetween {
+		d.x.Formula2 = fmt.Sprintf("%v", max)
+	}
+}
+
+// SetAllowBlank controls whether blank cells within the range are exempted
+// from validation.
+func (d DataValidation) SetAllowBlank(allowed bool) {
+	d.x.AllowBlankAttr = gooxml.Bool(allowed)
+}
+
+// SetPrompt sets the input prompt shown when a cell in the range is
+// selected.
+func (d DataValidation) SetPrompt(title, msg string) {
+	d.x.ShowInputMessageAttr = gooxml.Bool(true)
+	d.x.PromptTitleAttr = gooxml.String(title)
+	d.x.PromptAttr = gooxml.String(msg)
+}
+
+// SetError sets the error message shown when a cell is set to a value that
+// fails validation.
+func (d DataValidation) SetError(title, msg string) {
+	d.x.ShowErrorMessageAttr = gooxml.Bool(true)
+	d.x.ErrorTitleAttr = gooxml.String(title)
+	d.x.ErrorAttr = gooxml.String(msg)
+}
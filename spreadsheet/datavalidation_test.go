@@ -0,0 +1,98 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+)
+
+func TestSetListShortStaysInline(t *testing.T) {
+	s := newTestSheet()
+	dv := s.AddDataValidation()
+	dv.SetList([]string{"Red", "Green", "Blue"})
+
+	if dv.X().TypeAttr != sml.ST_DataValidationTypeList {
+		t.Fatalf("expected a list validation, got %v", dv.X().TypeAttr)
+	}
+	if dv.X().Formula1 != `"Red,Green,Blue"` {
+		t.Fatalf("unexpected inline formula: %s", dv.X().Formula1)
+	}
+}
+
+func TestSetListLongSwitchesToRangeHelper(t *testing.T) {
+	s := newTestSheet()
+	dv := s.AddDataValidation()
+
+	values := make([]string, 100)
+	for i := range values {
+		values[i] = strings.Repeat("x", 10) // >> 255 chars joined
+	}
+	dv.SetList(values)
+
+	if dv.X().Formula1 == "" || strings.Contains(dv.X().Formula1, ",") {
+		t.Fatalf("expected a range reference formula, got %q", dv.X().Formula1)
+	}
+	if !strings.Contains(dv.X().Formula1, listHelperColumn) {
+		t.Fatalf("expected the formula to reference the helper column %s, got %q", listHelperColumn, dv.X().Formula1)
+	}
+
+	// the helper column should end up hidden
+	hidden := false
+	for _, c := range s.x.Cols[0].Col {
+		if c.MinAttr == columnToIndex(listHelperColumn) && c.HiddenAttr != nil && *c.HiddenAttr {
+			hidden = true
+		}
+	}
+	if !hidden {
+		t.Fatalf("expected the helper column to be hidden")
+	}
+}
+
+func TestSetListMultipleLongListsDontOverlap(t *testing.T) {
+	s := newTestSheet()
+
+	values1 := make([]string, 50)
+	for i := range values1 {
+		values1[i] = strings.Repeat("a", 10)
+	}
+	values2 := make([]string, 50)
+	for i := range values2 {
+		values2[i] = strings.Repeat("b", 10)
+	}
+
+	dv1 := s.AddDataValidation()
+	dv1.SetList(values1)
+	dv2 := s.AddDataValidation()
+	dv2.SetList(values2)
+
+	if dv1.X().Formula1 == dv2.X().Formula1 {
+		t.Fatalf("expected the two long lists to get distinct helper ranges, both got %q", dv1.X().Formula1)
+	}
+
+	v, err := s.GetCellValue(listHelperColumn + "1")
+	if err != nil || v != values1[0] {
+		t.Fatalf("expected the first list's values to start at row 1, got %q, %v", v, err)
+	}
+	v, err = s.GetCellValue(fmt.Sprintf("%s%d", listHelperColumn, len(values1)+1))
+	if err != nil || v != values2[0] {
+		t.Fatalf("expected the second list's values to start right after the first list's, got %q, %v", v, err)
+	}
+}
+
+func TestValidationOpToST(t *testing.T) {
+	if ValidationOpGreaterThan.toST() != sml.ST_DataValidationOperatorGreaterThan {
+		t.Fatalf("ValidationOpGreaterThan mapped incorrectly")
+	}
+	if ValidationOp(255).toST() != sml.ST_DataValidationOperatorBetween {
+		t.Fatalf("unknown ValidationOp should default to between")
+	}
+}
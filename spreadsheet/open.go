@@ -0,0 +1,177 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+
+	"baliance.com/gooxml/common"
+	sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+)
+
+// workbookRelsTarget is the fixed, spec-defined location of the workbook
+// part's relationships, which map the r:id on each <sheet> in workbook.xml
+// to the worksheet part that holds its rows.
+const workbookRelsTarget = "xl/_rels/workbook.xml.rels"
+
+// opcRelationship is the XML shape of a single <Relationship> in a .rels
+// part; it's decoded directly rather than through common.Relationships
+// because all Open needs from it is the Id -> Target mapping.
+type opcRelationship struct {
+	IdAttr     string `xml:"Id,attr"`
+	TypeAttr   string `xml:"Type,attr"`
+	TargetAttr string `xml:"Target,attr"`
+}
+
+type opcRelationships struct {
+	Relationship []opcRelationship `xml:"Relationship"`
+}
+
+// sharedStringsTarget and stylesTarget are the spec-defined, conventional
+// locations of the shared-string table and style sheet parts. Unlike
+// worksheet parts, these aren't looked up through a relationship -- nearly
+// every XLSX writer, including Excel, places them here.
+const (
+	sharedStringsTarget = "xl/sharedStrings.xml"
+	stylesTarget        = "xl/styles.xml"
+)
+
+// Open reads an existing XLSX file and returns a Workbook that can be
+// inspected with Cell.GetValue/Cell.GetFormula/Sheet.GetCellValue, edited
+// through the usual Sheet/Row/Cell API, and saved back out.
+//
+// Open wires up each sheet's worksheet XML (rows, cells, merged cells,
+// formulas) along with the workbook's shared-string table and style sheet,
+// so Cell.GetValue resolves shared strings and applies number formats the
+// same way it does for a workbook built up in memory. It does not yet
+// reconstruct per-sheet hyperlink/drawing relationships.
+func Open(filename string) (*Workbook, error) {
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %s", filename, err)
+	}
+	defer zr.Close()
+
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	wbRoot, err := decodeXML(files, "xl/workbook.xml", func() interface{} { return sml.NewWorkbook() })
+	if err != nil {
+		return nil, err
+	}
+	wb := wbRoot.(*sml.Workbook)
+
+	rels, err := decodeRelationships(files, workbookRelsTarget)
+	if err != nil {
+		return nil, err
+	}
+	targetByID := map[string]string{}
+	for _, r := range rels.Relationship {
+		targetByID[r.IdAttr] = r.TargetAttr
+	}
+
+	if wb.Sheets == nil {
+		return nil, fmt.Errorf("error opening %s: workbook.xml has no <sheets>", filename)
+	}
+
+	var xws []*sml.Worksheet
+	for _, sheet := range wb.Sheets.Sheet {
+		target, ok := targetByID[sheet.IdAttr]
+		if !ok {
+			return nil, fmt.Errorf("error opening %s: sheet %q has no matching relationship", filename, sheet.NameAttr)
+		}
+		x, err := decodeXML(files, "xl/"+target, func() interface{} { return sml.NewWorksheet() })
+		if err != nil {
+			return nil, err
+		}
+		xws = append(xws, x.(*sml.Worksheet))
+	}
+
+	sstRoot, err := decodeOptionalXML(files, sharedStringsTarget, func() interface{} { return sml.NewSst() })
+	if err != nil {
+		return nil, err
+	}
+	stylesRoot, err := decodeOptionalXML(files, stylesTarget, func() interface{} { return sml.NewStyleSheet() })
+	if err != nil {
+		return nil, err
+	}
+
+	return &Workbook{
+		x:             wb,
+		xws:           xws,
+		xwsRels:       make([]common.Relationships, len(xws)),
+		SharedStrings: SharedStrings{sstRoot.(*sml.Sst)},
+		StyleSheet:    StyleSheet{stylesRoot.(*sml.StyleSheet)},
+	}, nil
+}
+
+// decodeXML unmarshals the zip entry named name into a fresh value produced
+// by new, returning an error that names the offending part on failure.
+func decodeXML(files map[string]*zip.File, name string, new func() interface{}) (interface{}, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("error opening xlsx: missing part %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error reading part %s: %s", name, err)
+	}
+	defer rc.Close()
+
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("error reading part %s: %s", name, err)
+	}
+
+	v := new()
+	if err := xml.Unmarshal(b, v); err != nil {
+		return nil, fmt.Errorf("error parsing part %s: %s", name, err)
+	}
+	return v, nil
+}
+
+// decodeOptionalXML behaves like decodeXML, except a missing part is not an
+// error: it simply returns the zero value produced by new, since parts like
+// sharedStrings.xml are omitted entirely by writers when there's nothing to
+// put in them.
+func decodeOptionalXML(files map[string]*zip.File, name string, new func() interface{}) (interface{}, error) {
+	if _, ok := files[name]; !ok {
+		return new(), nil
+	}
+	return decodeXML(files, name, new)
+}
+
+// decodeRelationships parses the .rels part at name.
+func decodeRelationships(files map[string]*zip.File, name string) (*opcRelationships, error) {
+	f, ok := files[name]
+	if !ok {
+		// a part with no relationships simply has no .rels file
+		return &opcRelationships{}, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %s", name, err)
+	}
+	defer rc.Close()
+
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %s", name, err)
+	}
+
+	rels := &opcRelationships{}
+	if err := xml.Unmarshal(b, rels); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %s", name, err)
+	}
+	return rels, nil
+}
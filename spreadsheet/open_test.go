@@ -0,0 +1,160 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+)
+
+func zipFiles(t *testing.T, contents map[string]string) map[string]*zip.File {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for name, content := range contents {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("error creating zip entry %s: %s", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("error writing zip entry %s: %s", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("error closing zip writer: %s", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reopening zip: %s", err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	return files
+}
+
+func TestDecodeRelationships(t *testing.T) {
+	files := zipFiles(t, map[string]string{
+		workbookRelsTarget: `<?xml version="1.0"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`,
+	})
+
+	rels, err := decodeRelationships(files, workbookRelsTarget)
+	if err != nil {
+		t.Fatalf("error decoding relationships: %s", err)
+	}
+	if len(rels.Relationship) != 1 || rels.Relationship[0].TargetAttr != "worksheets/sheet1.xml" {
+		t.Fatalf("unexpected relationships: %+v", rels.Relationship)
+	}
+}
+
+func TestDecodeRelationshipsMissingPart(t *testing.T) {
+	files := zipFiles(t, map[string]string{})
+	rels, err := decodeRelationships(files, workbookRelsTarget)
+	if err != nil {
+		t.Fatalf("expected a missing .rels part to be treated as empty, got error: %s", err)
+	}
+	if len(rels.Relationship) != 0 {
+		t.Fatalf("expected no relationships, got %+v", rels.Relationship)
+	}
+}
+
+func TestOpenMissingFile(t *testing.T) {
+	if _, err := Open("/nonexistent/path/to/file.xlsx"); err == nil {
+		t.Fatalf("expected an error opening a nonexistent file, got nil")
+	}
+}
+
+func TestDecodeOptionalXMLMissingPart(t *testing.T) {
+	files := zipFiles(t, map[string]string{})
+	v, err := decodeOptionalXML(files, sharedStringsTarget, func() interface{} { return 42 })
+	if err != nil {
+		t.Fatalf("expected a missing optional part to be treated as the zero value, got error: %s", err)
+	}
+	if v.(int) != 42 {
+		t.Fatalf("expected the zero value from new(), got %v", v)
+	}
+}
+
+func writeXLSXFixture(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("error creating fixture %s: %s", path, err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	files := map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <sheets>
+    <sheet name="Sheet1" sheetId="1" r:id="rId1"/>
+  </sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`,
+		"xl/worksheets/sheet1.xml": `<?xml version="1.0"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>
+    <row r="1">
+      <c r="A1" t="s"><v>0</v></c>
+    </row>
+  </sheetData>
+</worksheet>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="1" uniqueCount="1">
+  <si><t>Hello</t></si>
+</sst>`,
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("error creating zip entry %s: %s", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("error writing zip entry %s: %s", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("error closing zip writer: %s", err)
+	}
+}
+
+func TestOpenResolvesSharedStrings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.xlsx")
+	writeXLSXFixture(t, path)
+
+	wb, err := Open(path)
+	if err != nil {
+		t.Fatalf("error opening fixture: %s", err)
+	}
+	if len(wb.xws) != 1 {
+		t.Fatalf("expected 1 worksheet, got %d", len(wb.xws))
+	}
+
+	s := Sheet{wb, sml.NewCT_Sheet(), wb.xws[0]}
+	v, err := s.GetCellValue("A1")
+	if err != nil {
+		t.Fatalf("error getting cell value: %s", err)
+	}
+	if v != "Hello" {
+		t.Fatalf("expected the shared string \"Hello\", got %q", v)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"unicode/utf16"
+
+	"baliance.com/gooxml"
+	sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+)
+
+// defaultSpinCount is the number of hash iterations applied to a protection
+// password. ISO/IEC 29500 requires at least 100,000 to be considered
+// non-legacy.
+const defaultSpinCount = 100000
+
+// hashPassword computes the ISO/IEC 29500 SHA-512 password hash: a random
+// 16-byte salt is hashed together with the UTF-16LE password, then the
+// result is re-hashed spinCount times with the (little-endian) iteration
+// number appended each round. It returns the base64-encoded salt and hash.
+func hashPassword(password string, spinCount uint32) (salt string, hash string) {
+	saltBytes := make([]byte, 16)
+	if _, err := rand.Read(saltBytes); err != nil {
+		panic(err) // crypto/rand.Read only fails if the OS entropy source is broken
+	}
+	return base64.StdEncoding.EncodeToString(saltBytes), hashPasswordWithSalt(password, saltBytes, spinCount)
+}
+
+// hashPasswordWithSalt computes the hash described by hashPassword for a
+// given salt, returning it base64-encoded.
+func hashPasswordWithSalt(password string, saltBytes []byte, spinCount uint32) string {
+	pwBytes := utf16LEBytes(password)
+	h := sha512.Sum512(append(saltBytes, pwBytes...))
+	cur := h[:]
+	for i := uint32(0); i < spinCount; i++ {
+		var iter [4]byte
+		binary.LittleEndian.PutUint32(iter[:], i)
+		next := sha512.Sum512(append(cur, iter[:]...))
+		cur = next[:]
+	}
+
+	return base64.StdEncoding.EncodeToString(cur)
+}
+
+// utf16LEBytes encodes s as UTF-16LE, the encoding ISO/IEC 29500 requires
+// for the password bytes hashed into a protection password.
+func utf16LEBytes(s string) []byte {
+	u := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(u))
+	for i, v := range u {
+		binary.LittleEndian.PutUint16(b[2*i:], v)
+	}
+	return b
+}
+
+// SheetProtection configures which operations are disallowed on a protected
+// sheet and, optionally, the password required to unprotect it. Every field's
+// zero value matches Excel's "protect sheet" dialog defaults: selecting
+// cells is allowed (hence Disallow*, not Allow*, for those two fields) while
+// every other operation is restricted.
+type SheetProtection struct {
+	// Password, if non-empty, is required to remove protection from the
+	// sheet. If empty, the sheet is protected without a password.
+	Password string
+
+	DisallowSelectLockedCells   bool
+	DisallowSelectUnlockedCells bool
+	AllowFormatCells            bool
+	AllowFormatColumns          bool
+	AllowFormatRows             bool
+	AllowInsertColumns          bool
+	AllowInsertRows             bool
+	AllowInsertHyperlinks       bool
+	AllowDeleteColumns          bool
+	AllowDeleteRows             bool
+	AllowSort                   bool
+	AllowAutoFilter             bool
+	AllowPivotTables            bool
+	AllowObjects                bool
+	AllowScenarios              bool
+}
+
+// Protect enables sheet protection, restricting the sheet to the operations
+// allowed by opts.
+func (s Sheet) Protect(opts SheetProtection) {
+	sp := sml.NewCT_SheetProtection()
+	sp.SheetAttr = gooxml.Bool(true)
+
+	if opts.Password != "" {
+		salt, hash := hashPassword(opts.Password, defaultSpinCount)
+		sp.AlgorithmNameAttr = gooxml.String("SHA-512")
+		sp.SaltValueAttr = gooxml.String(salt)
+		sp.HashValueAttr = gooxml.String(hash)
+		sp.SpinCountAttr = gooxml.Uint32(defaultSpinCount)
+	}
+
+	// the granular flags are "disallow" flags in the schema: true means the
+	// operation is locked down. DisallowSelect* fields already match that
+	// polarity; every other field is an Allow* field, so is inverted.
+	sp.SelectLockedCellsAttr = gooxml.Bool(opts.DisallowSelectLockedCells)
+	sp.SelectUnlockedCellsAttr = gooxml.Bool(opts.DisallowSelectUnlockedCells)
+	sp.FormatCellsAttr = gooxml.Bool(!opts.AllowFormatCells)
+	sp.FormatColumnsAttr = gooxml.Bool(!opts.AllowFormatColumns)
+	sp.FormatRowsAttr = gooxml.Bool(!opts.AllowFormatRows)
+	sp.InsertColumnsAttr = gooxml.Bool(!opts.AllowInsertColumns)
+	sp.InsertRowsAttr = gooxml.Bool(!opts.AllowInsertRows)
+	sp.InsertHyperlinksAttr = gooxml.Bool(!opts.AllowInsertHyperlinks)
+	sp.DeleteColumnsAttr = gooxml.Bool(!opts.AllowDeleteColumns)
+	sp.DeleteRowsAttr = gooxml.Bool(!opts.AllowDeleteRows)
+	sp.SortAttr = gooxml.Bool(!opts.AllowSort)
+	sp.AutoFilterAttr = gooxml.Bool(!opts.AllowAutoFilter)
+	sp.PivotTablesAttr = gooxml.Bool(!opts.AllowPivotTables)
+	sp.ObjectsAttr = gooxml.Bool(!opts.AllowObjects)
+	sp.ScenariosAttr = gooxml.Bool(!opts.AllowScenarios)
+
+	s.x.SheetProtection = sp
+}
+
+// WorkbookProtection configures which workbook-level operations are
+// disallowed and, optionally, the password required to remove protection.
+type WorkbookProtection struct {
+	// Password, if non-empty, is required to remove protection from the
+	// workbook. If empty, the workbook is protected without a password.
+	Password string
+
+	AllowStructure bool
+	AllowWindows   bool
+}
+
+// Protect enables workbook protection, restricting the workbook to the
+// operations allowed by opts.
+func (w *Workbook) Protect(opts WorkbookProtection) {
+	wp := sml.NewCT_WorkbookProtection()
+
+	if opts.Password != "" {
+		salt, hash := hashPassword(opts.Password, defaultSpinCount)
+		wp.WorkbookAlgorithmNameAttr = gooxml.String("SHA-512")
+		wp.WorkbookSaltValueAttr = gooxml.String(salt)
+		wp.WorkbookHashValueAttr = gooxml.String(hash)
+		wp.WorkbookSpinCountAttr = gooxml.Uint32(defaultSpinCount)
+	}
+
+	wp.LockStructureAttr = gooxml.Bool(!opts.AllowStructure)
+	wp.LockWindowsAttr = gooxml.Bool(!opts.AllowWindows)
+
+	w.x.WorkbookProtection = wp
+}
@@ -0,0 +1,67 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestUTF16LEBytes(t *testing.T) {
+	got := utf16LEBytes("AB")
+	want := []byte{'A', 0, 'B', 0}
+	if len(got) != len(want) {
+		t.Fatalf("utf16LEBytes(%q) = %v, want %v", "AB", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("utf16LEBytes(%q) = %v, want %v", "AB", got, want)
+		}
+	}
+}
+
+func TestHashPasswordIsBase64AndSalted(t *testing.T) {
+	salt1, hash1 := hashPassword("hunter2", 1000)
+	salt2, hash2 := hashPassword("hunter2", 1000)
+
+	if _, err := base64.StdEncoding.DecodeString(salt1); err != nil {
+		t.Fatalf("salt %q is not valid base64: %s", salt1, err)
+	}
+	if _, err := base64.StdEncoding.DecodeString(hash1); err != nil {
+		t.Fatalf("hash %q is not valid base64: %s", hash1, err)
+	}
+
+	if salt1 == salt2 {
+		t.Fatalf("expected a fresh random salt on each call, got the same salt twice")
+	}
+	if hash1 == hash2 {
+		t.Fatalf("expected different hashes for different salts, got the same hash twice")
+	}
+
+	saltBytes, _ := base64.StdEncoding.DecodeString(salt1)
+	if len(saltBytes) != 16 {
+		t.Fatalf("expected a 16-byte salt, got %d bytes", len(saltBytes))
+	}
+}
+
+func TestHashPasswordDeterministicGivenSameInputs(t *testing.T) {
+	// hashPassword always generates a fresh salt, so the iteration logic is
+	// checked through hashPasswordWithSalt with a fixed salt: the same
+	// password, salt and spin count give the same hash, and a different spin
+	// count gives a different one.
+	salt := make([]byte, 16)
+	hash1 := hashPasswordWithSalt("hunter2", salt, 1000)
+	hash2 := hashPasswordWithSalt("hunter2", salt, 1000)
+	if hash1 != hash2 {
+		t.Fatalf("expected the same hash for the same password, salt and spin count, got %q and %q", hash1, hash2)
+	}
+	shortHash := hashPasswordWithSalt("hunter2", salt, 1)
+	if shortHash == hash1 {
+		t.Fatalf("expected spinCount to affect the resulting hash")
+	}
+}
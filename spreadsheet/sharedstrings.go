@@ -0,0 +1,22 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+
+// SharedStrings wraps a workbook's sharedStrings.xml, the table that
+// shared-string cells (sml.CT_Cell.TAttr == sml.ST_CellTypeS) index into
+// rather than storing their text inline.
+type SharedStrings struct {
+	x *sml.Sst
+}
+
+// X returns the inner wrapped XML type.
+func (s SharedStrings) X() *sml.Sst {
+	return s.x
+}
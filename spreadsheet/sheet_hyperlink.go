@@ -0,0 +1,86 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"fmt"
+
+	"baliance.com/gooxml/common"
+	sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+)
+
+// UpdateHyperlink updates the URL of the hyperlink already set on cellRef,
+// reusing its existing relationship ID rather than allocating a new
+// relationship as AddHyperlink does. It returns an error if the cell has no
+// hyperlink.
+func (s Sheet) UpdateHyperlink(cellRef string, url string) error {
+	hl, rels := s.findHyperlink(cellRef)
+	if hl == nil {
+		return fmt.Errorf("cell %s has no hyperlink to update", cellRef)
+	}
+	if hl.IdAttr == nil {
+		return fmt.Errorf("cell %s has a location-only hyperlink, not a URL hyperlink", cellRef)
+	}
+	for _, rel := range rels.X().Relationship {
+		if rel.IdAttr == *hl.IdAttr {
+			rel.TargetAttr = url
+			return nil
+		}
+	}
+	return fmt.Errorf("cell %s's hyperlink relationship %s not found", cellRef, *hl.IdAttr)
+}
+
+// RemoveHyperlink removes the hyperlink on cellRef, if any, along with its
+// relationship. It is a no-op if the cell has no hyperlink.
+func (s Sheet) RemoveHyperlink(cellRef string) {
+	hl, rels := s.findHyperlink(cellRef)
+	if hl == nil {
+		return
+	}
+
+	if hl.IdAttr != nil {
+		rel := rels.X()
+		for i, r := range rel.Relationship {
+			if r.IdAttr == *hl.IdAttr {
+				copy(rel.Relationship[i:], rel.Relationship[i+1:])
+				rel.Relationship = rel.Relationship[:len(rel.Relationship)-1]
+				break
+			}
+		}
+	}
+
+	links := s.x.Hyperlinks.Hyperlink
+	for i, h := range links {
+		if h == hl {
+			copy(links[i:], links[i+1:])
+			s.x.Hyperlinks.Hyperlink = links[:len(links)-1]
+			break
+		}
+	}
+	if len(s.x.Hyperlinks.Hyperlink) == 0 {
+		s.x.Hyperlinks = nil
+	}
+}
+
+// findHyperlink locates the hyperlink entry for cellRef along with the
+// sheet's relationships part, returning nil if either can't be found.
+func (s Sheet) findHyperlink(cellRef string) (*sml.CT_Hyperlink, *common.Relationships) {
+	if s.x.Hyperlinks == nil {
+		return nil, nil
+	}
+	rels := s.w.relationshipsFor(s.x)
+	if rels == nil {
+		return nil, nil
+	}
+	for _, hl := range s.x.Hyperlinks.Hyperlink {
+		if hl.RefAttr == cellRef {
+			return hl, rels
+		}
+	}
+	return nil, nil
+}
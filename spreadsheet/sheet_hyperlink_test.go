@@ -0,0 +1,103 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"testing"
+
+	"baliance.com/gooxml/common"
+	sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+)
+
+// newHyperlinkTestSheet returns a sheet with a single hyperlink, registered
+// with the workbook's relationships the way AddHyperlink/SetHyperlink would,
+// so UpdateHyperlink/RemoveHyperlink can be exercised end-to-end.
+func newHyperlinkTestSheet(cellRef, url string) Sheet {
+	x := sml.NewWorksheet()
+	x.SheetData = sml.NewCT_SheetData()
+	w := &Workbook{xws: []*sml.Worksheet{x}, xwsRels: make([]common.Relationships, 1)}
+
+	hl := w.xwsRels[0].AddHyperlink(url)
+	id := hl.ID()
+	x.Hyperlinks = sml.NewCT_Hyperlinks()
+	ctHl := sml.NewCT_Hyperlink()
+	ctHl.RefAttr = cellRef
+	ctHl.IdAttr = &id
+	x.Hyperlinks.Hyperlink = append(x.Hyperlinks.Hyperlink, ctHl)
+
+	return Sheet{w, sml.NewCT_Sheet(), x}
+}
+
+func TestUpdateHyperlinkNoHyperlinkOnCell(t *testing.T) {
+	s := newTestSheet()
+	if err := s.UpdateHyperlink("A1", "http://example.com"); err == nil {
+		t.Fatalf("expected an error updating a hyperlink on a cell that has none")
+	}
+}
+
+func TestRemoveHyperlinkNoHyperlinkOnCellIsNoOp(t *testing.T) {
+	s := newTestSheet()
+	// should not panic, and should leave the sheet's Hyperlinks untouched
+	s.RemoveHyperlink("A1")
+	if s.x.Hyperlinks != nil {
+		t.Fatalf("expected Hyperlinks to remain nil, got %+v", s.x.Hyperlinks)
+	}
+}
+
+func TestUpdateHyperlinkUpdatesURLKeepingRelationshipID(t *testing.T) {
+	s := newHyperlinkTestSheet("A1", "http://old.example.com")
+	hl, rels := s.findHyperlink("A1")
+	if hl == nil || hl.IdAttr == nil {
+		t.Fatalf("expected the fixture's hyperlink to be found")
+	}
+	wantID := *hl.IdAttr
+
+	if err := s.UpdateHyperlink("A1", "http://new.example.com"); err != nil {
+		t.Fatalf("error updating hyperlink: %s", err)
+	}
+
+	hl2, _ := s.findHyperlink("A1")
+	if hl2.IdAttr == nil || *hl2.IdAttr != wantID {
+		t.Fatalf("expected the relationship ID to stay %s after updating, got %v", wantID, hl2.IdAttr)
+	}
+
+	found := false
+	for _, rel := range rels.X().Relationship {
+		if rel.IdAttr == wantID {
+			found = true
+			if rel.TargetAttr != "http://new.example.com" {
+				t.Fatalf("expected the relationship's target to be updated, got %q", rel.TargetAttr)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected relationship %s to still exist after updating", wantID)
+	}
+}
+
+func TestRemoveHyperlinkRemovesEntryAndRelationship(t *testing.T) {
+	s := newHyperlinkTestSheet("A1", "http://example.com")
+	_, rels := s.findHyperlink("A1")
+
+	s.RemoveHyperlink("A1")
+
+	if s.x.Hyperlinks != nil {
+		t.Fatalf("expected Hyperlinks to be cleared once the last hyperlink is removed, got %+v", s.x.Hyperlinks)
+	}
+	if len(rels.X().Relationship) != 0 {
+		t.Fatalf("expected the hyperlink's relationship to be removed too, got %+v", rels.X().Relationship)
+	}
+}
+
+func TestFindHyperlinkNilHyperlinks(t *testing.T) {
+	s := newTestSheet()
+	hl, rels := s.findHyperlink("A1")
+	if hl != nil || rels != nil {
+		t.Fatalf("expected findHyperlink to return nil, nil when the sheet has no hyperlinks at all, got %v, %v", hl, rels)
+	}
+}
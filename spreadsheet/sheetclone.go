@@ -0,0 +1,140 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+
+	"baliance.com/gooxml/common"
+	sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+)
+
+// CopySheet makes an independent copy of the sheet at srcIndex (as returned
+// by Workbook.Sheets), appends it to the workbook as newName and returns it.
+// The copy includes all of the source sheet's worksheet XML -- rows, cells,
+// merged cells, views, data validations, protection settings, conditional
+// formatting, page setup and hyperlinks -- with hyperlink relationship IDs
+// rewritten so the two sheets share no rIds. The one exception is the
+// drawing: CopySheet drops it rather than pointing the new sheet at the
+// same drawing part (which real charts/images shouldn't share between
+// sheets) or at a relationship that doesn't resolve to anything. Callers
+// that need the drawing too should build a new one and call
+// Sheet.SetDrawing on the clone.
+func (w *Workbook) CopySheet(srcIndex int, newName string) (Sheet, error) {
+	srcSheets := w.Sheets()
+	if srcIndex < 0 || srcIndex >= len(srcSheets) {
+		return Sheet{}, fmt.Errorf("invalid sheet index %d, have %d sheets", srcIndex, len(srcSheets))
+	}
+	src := srcSheets[srcIndex]
+
+	dst := w.AddSheet()
+	dst.SetName(newName)
+	if err := dst.copyContentFrom(src); err != nil {
+		return Sheet{}, err
+	}
+	return dst, nil
+}
+
+// Clone returns an independent copy of the sheet, appended to the same
+// workbook with the name "<original name> Copy". To control the new name,
+// use Workbook.CopySheet instead.
+func (s Sheet) Clone() Sheet {
+	for i, sheet := range s.w.Sheets() {
+		if sheet.x == s.x {
+			clone, err := s.w.CopySheet(i, s.Name()+" Copy")
+			if err != nil {
+				log.Printf("error cloning sheet: %s", err)
+				return Sheet{}
+			}
+			return clone
+		}
+	}
+	log.Printf("error cloning sheet: sheet not found in workbook")
+	return Sheet{}
+}
+
+// copyContentFrom deep copies the entirety of src's worksheet XML onto s,
+// then rewrites the relationship IDs of any hyperlinks so the two sheets no
+// longer share any rIds. See CopySheet's docs for the drawing exception.
+func (s Sheet) copyContentFrom(src Sheet) error {
+	cp, err := cloneWorksheetXML(src.x)
+	if err != nil {
+		return err
+	}
+
+	// Replace s.x's contents wholesale with the deep copy rather than
+	// picking individual fields, so nothing (SheetViews, DataValidations,
+	// SheetProtection, ConditionalFormatting, PageSetup, ...) is silently
+	// dropped. s.x's identity (its pointer, already registered in
+	// w.xws/w.xwsRels) is preserved; only what it points to changes.
+	*s.x = *cp
+
+	// The copied worksheet XML still references the source's drawing part
+	// by rId; see CopySheet's docs for why that isn't carried over.
+	s.x.Drawing = nil
+
+	if s.x.Hyperlinks == nil {
+		return nil
+	}
+
+	srcRels := src.w.relationshipsFor(src.x)
+	dstRels := s.w.relationshipsFor(s.x)
+	if srcRels == nil || dstRels == nil {
+		return nil
+	}
+
+	for _, hl := range s.x.Hyperlinks.Hyperlink {
+		if hl.IdAttr == nil {
+			continue
+		}
+		newID, ok := cloneRelationship(srcRels, dstRels, *hl.IdAttr)
+		if !ok {
+			continue
+		}
+		hl.IdAttr = &newID
+	}
+	return nil
+}
+
+// cloneWorksheetXML returns a deep, independent copy of a worksheet's XML:
+// mutating the copy (or the source) has no effect on the other.
+func cloneWorksheetXML(x *sml.Worksheet) (*sml.Worksheet, error) {
+	buf, err := xml.Marshal(x)
+	if err != nil {
+		return nil, fmt.Errorf("error cloning sheet contents: %s", err)
+	}
+	cp := sml.NewWorksheet()
+	if err := xml.Unmarshal(buf, cp); err != nil {
+		return nil, fmt.Errorf("error cloning sheet contents: %s", err)
+	}
+	return cp, nil
+}
+
+// relationshipsFor returns the relationships part for a worksheet, or nil if
+// the worksheet isn't registered with the workbook.
+func (w *Workbook) relationshipsFor(x *sml.Worksheet) *common.Relationships {
+	for i, ws := range w.xws {
+		if ws == x {
+			return &w.xwsRels[i]
+		}
+	}
+	return nil
+}
+
+// cloneRelationship copies the hyperlink relationship identified by oldID
+// from src to dst, returning the new ID it was assigned in dst.
+func cloneRelationship(src, dst *common.Relationships, oldID string) (string, bool) {
+	for _, rel := range src.X().Relationship {
+		if rel.IdAttr == oldID {
+			return dst.AddHyperlink(rel.TargetAttr).ID(), true
+		}
+	}
+	return "", false
+}
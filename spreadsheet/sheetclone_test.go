@@ -0,0 +1,99 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"testing"
+
+	"baliance.com/gooxml"
+	"baliance.com/gooxml/common"
+	sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+)
+
+func TestCloneWorksheetXMLIsIndependent(t *testing.T) {
+	src := sml.NewWorksheet()
+	src.SheetData = sml.NewCT_SheetData()
+	row := sml.NewCT_Row()
+	row.RAttr = gooxml.Uint32(1)
+	src.SheetData.Row = append(src.SheetData.Row, row)
+
+	src.DataValidations = sml.NewCT_DataValidations()
+	dv := sml.NewCT_DataValidation()
+	dv.SqrefAttr = "A1:A10"
+	src.DataValidations.DataValidation = append(src.DataValidations.DataValidation, dv)
+
+	cp, err := cloneWorksheetXML(src)
+	if err != nil {
+		t.Fatalf("error cloning worksheet: %s", err)
+	}
+
+	if len(cp.SheetData.Row) != 1 || *cp.SheetData.Row[0].RAttr != 1 {
+		t.Fatalf("expected the cloned row to survive, got %+v", cp.SheetData.Row)
+	}
+	if cp.DataValidations == nil || len(cp.DataValidations.DataValidation) != 1 {
+		t.Fatalf("expected the cloned data validation to survive, got %+v", cp.DataValidations)
+	}
+
+	// mutating the copy must not affect the source, and vice versa
+	cp.SheetData.Row[0].RAttr = gooxml.Uint32(2)
+	if *src.SheetData.Row[0].RAttr != 1 {
+		t.Fatalf("mutating the clone affected the source row number")
+	}
+	cp.DataValidations.DataValidation[0].SqrefAttr = "B1:B10"
+	if src.DataValidations.DataValidation[0].SqrefAttr != "A1:A10" {
+		t.Fatalf("mutating the clone affected the source data validation")
+	}
+}
+
+func TestCopyContentFromRewritesHyperlinkRelationship(t *testing.T) {
+	srcX := sml.NewWorksheet()
+	srcX.SheetData = sml.NewCT_SheetData()
+	srcW := &Workbook{xws: []*sml.Worksheet{srcX}, xwsRels: make([]common.Relationships, 1)}
+
+	srcHl := srcW.xwsRels[0].AddHyperlink("http://example.com/src")
+	srcID := srcHl.ID()
+	srcX.Hyperlinks = sml.NewCT_Hyperlinks()
+	ctHl := sml.NewCT_Hyperlink()
+	ctHl.RefAttr = "A1"
+	ctHl.IdAttr = &srcID
+	srcX.Hyperlinks.Hyperlink = append(srcX.Hyperlinks.Hyperlink, ctHl)
+	src := Sheet{srcW, sml.NewCT_Sheet(), srcX}
+
+	dstX := sml.NewWorksheet()
+	dstX.SheetData = sml.NewCT_SheetData()
+	dstW := &Workbook{xws: []*sml.Worksheet{dstX}, xwsRels: make([]common.Relationships, 1)}
+	dst := Sheet{dstW, sml.NewCT_Sheet(), dstX}
+
+	if err := dst.copyContentFrom(src); err != nil {
+		t.Fatalf("error copying sheet content: %s", err)
+	}
+
+	if dstX.Hyperlinks == nil || len(dstX.Hyperlinks.Hyperlink) != 1 {
+		t.Fatalf("expected the hyperlink to be copied onto the destination, got %+v", dstX.Hyperlinks)
+	}
+	copied := dstX.Hyperlinks.Hyperlink[0]
+	if copied.RefAttr != "A1" {
+		t.Fatalf("expected the copied hyperlink to keep its cell reference, got %q", copied.RefAttr)
+	}
+	if copied.IdAttr == nil {
+		t.Fatalf("expected the copied hyperlink to have a relationship ID")
+	}
+	if *copied.IdAttr == srcID {
+		t.Fatalf("expected the copied hyperlink to be rewritten to a new relationship ID in the destination sheet, still had %s", srcID)
+	}
+
+	found := false
+	for _, rel := range dstW.xwsRels[0].X().Relationship {
+		if rel.IdAttr == *copied.IdAttr && rel.TargetAttr == "http://example.com/src" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected the destination's relationships to contain a relationship %s pointing at the source's hyperlink target", *copied.IdAttr)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"baliance.com/gooxml"
+	sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+)
+
+// StreamWriter writes rows to a sheet without holding the whole row set in
+// memory as live sml.CT_Row/sml.CT_Cell objects while the rows are being
+// produced. Rows are serialized to a temporary file as they're added via
+// SetRow, each one discarded from memory as soon as it's written to disk, so
+// peak memory during the write loop stays flat regardless of row count --
+// unlike repeated calls to AddNumberedRow, which keeps every row resident
+// (and re-sorts s.x.SheetData.Row) for the lifetime of the sheet.
+//
+// Flush decodes the streamed rows back into sml.CT_Row and appends them to
+// s.x.SheetData.Row, which is the only sheet-data representation this
+// package's part-writing code knows how to serialize; fully bypassing that
+// object graph (splicing the temp file's bytes directly into the worksheet
+// part at Save) would require hooking the workbook's part-writer, which
+// isn't present in this tree. Flush is therefore a one-time O(n)
+// materialization, not an O(1) one -- the memory win StreamWriter provides
+// is bounding the write loop itself, not the final save.
+type StreamWriter struct {
+	sheet  Sheet
+	file   *os.File
+	closed bool
+
+	lastRow        uint32
+	haveLastRow    bool
+	existingRows   map[uint32]bool
+	maxExistingRow uint32
+}
+
+// StreamWriter returns a StreamWriter for bulk writing rows to the sheet.
+// Rows must be added via SetRow in increasing order of rowNum, using cells
+// built with Sheet.NewDetachedCell rather than Sheet.Cell/Row.Cell (which
+// immediately append to and re-sort s.x.SheetData.Row -- exactly the cost
+// this type exists to avoid, and which would leave the row written twice if
+// also passed to SetRow). The sheet must not otherwise be modified until
+// Flush has been called.
+func (s Sheet) StreamWriter() (*StreamWriter, error) {
+	f, err := ioutil.TempFile("", "gooxml-streamwriter")
+	if err != nil {
+		return nil, fmt.Errorf("error creating stream writer temp file: %s", err)
+	}
+
+	existing := map[uint32]bool{}
+	maxExisting := uint32(0)
+	for _, r := range s.x.SheetData.Row {
+		if r.RAttr != nil {
+			existing[*r.RAttr] = true
+			if *r.RAttr > maxExisting {
+				maxExisting = *r.RAttr
+			}
+		}
+	}
+	return &StreamWriter{sheet: s, file: f, existingRows: existing, maxExistingRow: maxExisting}, nil
+}
+
+// NewDetachedCell returns a new Cell identified by the cell reference ref
+// (e.g. "B5") that is not attached to any row in the sheet. Unlike
+// Sheet.Cell/Row.Cell, constructing a detached cell has no effect on
+// s.x.SheetData.Row, so it's safe to build up a batch of cells for
+// StreamWriter.SetRow without that row being written to the sheet twice.
+// ref's row component must match the rowNum the cell is later passed to
+// SetRow with.
+func (s Sheet) NewDetachedCell(ref string) Cell {
+	x := sml.NewCT_Cell()
+	x.RAttr = gooxml.String(ref)
+	return Cell{s.w, nil, x}
+}
+
+// SetRow writes a row of cells to the stream. rowNum must be greater than the
+// rowNum of any previously streamed row and greater than every row already
+// present in the sheet when the StreamWriter was created -- StreamWriter only
+// ever appends, so Flush can add the streamed rows to the end of
+// s.x.SheetData.Row without re-sorting it.
+func (sw *StreamWriter) SetRow(rowNum uint32, cells []Cell) error {
+	if sw.closed {
+		return fmt.Errorf("gooxml: SetRow called on a StreamWriter after Flush")
+	}
+	if sw.existingRows[rowNum] {
+		return fmt.Errorf("gooxml: row %d already exists in the sheet; StreamWriter cannot be used to overwrite existing rows", rowNum)
+	}
+	if rowNum <= sw.maxExistingRow {
+		return fmt.Errorf("gooxml: row %d is not after the sheet's existing rows (highest existing row is %d); StreamWriter can only append rows", rowNum, sw.maxExistingRow)
+	}
+	if sw.haveLastRow && rowNum <= sw.lastRow {
+		return fmt.Errorf("gooxml: SetRow called with row %d, which is not greater than the previous row %d", rowNum, sw.lastRow)
+	}
+	sw.lastRow, sw.haveLastRow = rowNum, true
+
+	r := sml.NewCT_Row()
+	r.RAttr = gooxml.Uint32(rowNum)
+	for _, c := range cells {
+		r.C = append(r.C, c.X())
+	}
+
+	if err := xml.NewEncoder(sw.file).Encode(r); err != nil {
+		return fmt.Errorf("error streaming row %d: %s", rowNum, err)
+	}
+	return nil
+}
+
+// Flush appends the streamed rows to the sheet's worksheet XML. Because
+// SetRow already enforces strictly increasing row numbers that are all
+// greater than any row present when the StreamWriter was created, the
+// combined row set is already in order and Flush does not need to re-sort it.
+// The StreamWriter must not be used after Flush is called.
+func (sw *StreamWriter) Flush() error {
+	if sw.closed {
+		return nil
+	}
+	sw.closed = true
+	defer os.Remove(sw.file.Name())
+	defer sw.file.Close()
+
+	if _, err := sw.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error rewinding stream writer temp file: %s", err)
+	}
+
+	dec := xml.NewDecoder(sw.file)
+	for {
+		r := sml.NewCT_Row()
+		if err := dec.Decode(r); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("error reading streamed rows: %s", err)
+		}
+		sw.sheet.x.SheetData.Row = append(sw.sheet.x.SheetData.Row, r)
+	}
+	return nil
+}
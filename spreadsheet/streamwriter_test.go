@@ -0,0 +1,139 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+)
+
+func newTestSheet() Sheet {
+	x := sml.NewWorksheet()
+	x.SheetData = sml.NewCT_SheetData()
+	return Sheet{&Workbook{}, sml.NewCT_Sheet(), x}
+}
+
+func TestStreamWriterWritesRowsInOrder(t *testing.T) {
+	s := newTestSheet()
+	sw, err := s.StreamWriter()
+	if err != nil {
+		t.Fatalf("error creating StreamWriter: %s", err)
+	}
+
+	const numRows = 1000
+	for i := uint32(1); i <= numRows; i++ {
+		cell := s.NewDetachedCell(fmt.Sprintf("A%d", i))
+		if err := sw.SetRow(i, []Cell{cell}); err != nil {
+			t.Fatalf("error setting row %d: %s", i, err)
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		t.Fatalf("error flushing: %s", err)
+	}
+
+	if len(s.x.SheetData.Row) != numRows {
+		t.Fatalf("expected %d rows, got %d", numRows, len(s.x.SheetData.Row))
+	}
+	for i, r := range s.x.SheetData.Row {
+		want := uint32(i + 1)
+		if r.RAttr == nil || *r.RAttr != want {
+			t.Fatalf("row %d: expected row number %d, got %v", i, want, r.RAttr)
+		}
+	}
+}
+
+func TestStreamWriterRejectsNonIncreasingRows(t *testing.T) {
+	s := newTestSheet()
+	sw, _ := s.StreamWriter()
+
+	if err := sw.SetRow(2, []Cell{s.NewDetachedCell("A2")}); err != nil {
+		t.Fatalf("error setting row 2: %s", err)
+	}
+	if err := sw.SetRow(2, []Cell{s.NewDetachedCell("A2")}); err == nil {
+		t.Fatalf("expected an error re-using row 2, got nil")
+	}
+	if err := sw.SetRow(1, []Cell{s.NewDetachedCell("A1")}); err == nil {
+		t.Fatalf("expected an error going backwards to row 1, got nil")
+	}
+}
+
+func TestStreamWriterRejectsRowsThatAlreadyExist(t *testing.T) {
+	s := newTestSheet()
+	s.Row(5) // creates row 5 via the usual Sheet.Row append-and-resort path
+
+	sw, _ := s.StreamWriter()
+	if err := sw.SetRow(5, []Cell{s.NewDetachedCell("A5")}); err == nil {
+		t.Fatalf("expected an error re-streaming a row that already exists, got nil")
+	}
+}
+
+func TestStreamWriterRejectsRowBeforeHighestExistingRow(t *testing.T) {
+	s := newTestSheet()
+	s.Row(10) // a footer row added before streaming begins
+
+	sw, _ := s.StreamWriter()
+	if err := sw.SetRow(5, []Cell{s.NewDetachedCell("A5")}); err == nil {
+		t.Fatalf("expected an error streaming row 5 after an existing row 10, got nil")
+	}
+	// a row after the existing highest row is still fine
+	if err := sw.SetRow(11, []Cell{s.NewDetachedCell("A11")}); err != nil {
+		t.Fatalf("error setting row 11: %s", err)
+	}
+}
+
+// TestStreamWriterBoundedMemory is a coarse check that SetRow does not keep
+// every streamed row resident in memory: the live heap sampled at the end of
+// a large write should stay within a small multiple of the live heap sampled
+// partway through, rather than growing linearly with the rows written.
+func TestStreamWriterBoundedMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large write in short mode")
+	}
+
+	s := newTestSheet()
+	sw, err := s.StreamWriter()
+	if err != nil {
+		t.Fatalf("error creating StreamWriter: %s", err)
+	}
+
+	const numRows = 100000
+	var early, late uint64
+
+	for i := uint32(1); i <= numRows; i++ {
+		cell := s.NewDetachedCell(fmt.Sprintf("A%d", i))
+		if err := sw.SetRow(i, []Cell{cell}); err != nil {
+			t.Fatalf("error setting row %d: %s", i, err)
+		}
+		if i == numRows/10 {
+			early = allocatedBytes()
+		}
+		if i == numRows {
+			late = allocatedBytes()
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		t.Fatalf("error flushing: %s", err)
+	}
+
+	// generous bound: heap growth between the 10% and 100% marks of the
+	// write loop should not be proportional to the 9x growth in rows
+	// written, which is what a fully resident row slice would produce.
+	if late > early*4 {
+		t.Fatalf("heap usage grew from %d to %d bytes writing the remaining 90%% of rows; SetRow may be retaining rows in memory", early, late)
+	}
+}
+
+func allocatedBytes() uint64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
@@ -0,0 +1,84 @@
+// Copyright 2017 Baliance. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased by contacting sales@baliance.com.
+
+package spreadsheet
+
+import sml "baliance.com/gooxml/schema/schemas.openxmlformats.org/spreadsheetml"
+
+// StyleSheet wraps a workbook's styles.xml, giving cell style indices
+// (sml.CT_Cell.SAttr) access to the number formats and fonts they reference.
+type StyleSheet struct {
+	x *sml.StyleSheet
+}
+
+// X returns the inner wrapped XML type.
+func (s StyleSheet) X() *sml.StyleSheet {
+	return s.x
+}
+
+// builtinNumFmts are the number formats ISO/IEC 29500 reserves IDs 0-163 for,
+// which are implied rather than spelled out in a workbook's <numFmts>
+// element. Only a few common fixed-decimal and percentage formats are
+// listed here; formats
+// this table doesn't cover (dates, currencies, custom sections) are left to
+// the caller's raw-value fallback.
+var builtinNumFmts = map[uint32]string{
+	0:  "General",
+	1:  "0",
+	2:  "0.00",
+	3:  "#,##0",
+	4:  "#,##0.00",
+	9:  "0%",
+	10: "0.00%",
+}
+
+// GetNumberFormat returns the number format code applied by the cell style
+// at styleIdx (a sml.CT_Cell's SAttr), checking the workbook's custom
+// <numFmts> before falling back to the built-in formats. It returns
+// ok=false if styleIdx is out of range or doesn't specify a number format.
+func (s StyleSheet) GetNumberFormat(styleIdx uint32) (string, bool) {
+	if s.x == nil || s.x.CellXfs == nil || int(styleIdx) >= len(s.x.CellXfs.Xf) {
+		return "", false
+	}
+	xf := s.x.CellXfs.Xf[styleIdx]
+	if xf.NumFmtIdAttr == nil {
+		return "", false
+	}
+	id := *xf.NumFmtIdAttr
+
+	if s.x.NumFmts != nil {
+		for _, nf := range s.x.NumFmts.NumFmt {
+			if nf.NumFmtIdAttr == id {
+				return nf.FormatCodeAttr, true
+			}
+		}
+	}
+	if f, ok := builtinNumFmts[id]; ok {
+		return f, true
+	}
+	return "", false
+}
+
+// FontMetrics returns the point size and bold/italic flags of the font used
+// by the cell style at styleIdx (a sml.CT_Cell's SAttr). It returns
+// ok=false if styleIdx is out of range or its font can't be resolved.
+func (s StyleSheet) FontMetrics(styleIdx uint32) (size float64, bold bool, italic bool, ok bool) {
+	if s.x == nil || s.x.CellXfs == nil || int(styleIdx) >= len(s.x.CellXfs.Xf) {
+		return 0, false, false, false
+	}
+	xf := s.x.CellXfs.Xf[styleIdx]
+	if xf.FontIdAttr == nil || s.x.Fonts == nil || int(*xf.FontIdAttr) >= len(s.x.Fonts.Font) {
+		return 0, false, false, false
+	}
+	f := s.x.Fonts.Font[*xf.FontIdAttr]
+	if f.Sz == nil {
+		return 0, false, false, false
+	}
+	bold = f.B != nil && (f.B.ValAttr == nil || *f.B.ValAttr)
+	italic = f.I != nil && (f.I.ValAttr == nil || *f.I.ValAttr)
+	return f.Sz.ValAttr, bold, italic, true
+}